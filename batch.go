@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli"
+)
+
+var batchTx = cli.Command{
+	Name:  "batch",
+	Usage: "construct, sign, and optionally broadcast many transactions from a file",
+	Flags: append(append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "file",
+			Usage: "path to a newline-delimited or JSON-array file of transaction descriptions",
+		},
+		cli.StringFlag{
+			Name:  "chainID",
+			Value: "1",
+			Usage: "chain id to sign the transactions for",
+		},
+		cli.Int64Flag{
+			Name:  "startNonce",
+			Value: -1,
+			Usage: "nonce to start from when a transaction doesn't specify one; if unset, queried from --rpc",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Value: "hex",
+			Usage: "output mode: hex (concatenated, one per line), json (array of hex strings), or broadcast",
+		},
+	}, keySourceFlags...), broadcastFlags...),
+	Action: func(c *cli.Context) error {
+		reqs, err := loadBatchFile(c.String("file"))
+		if err != nil {
+			return err
+		}
+
+		chainID, ok := big.NewInt(0).SetString(c.String("chainID"), 10)
+		if !ok {
+			return fmt.Errorf("invalid value for chain id")
+		}
+
+		signer, err := signerFromContext(c)
+		if err != nil {
+			return err
+		}
+
+		nonce, err := startingNonce(c, signer.Address())
+		if err != nil {
+			return err
+		}
+
+		var bc Broadcaster
+		out := c.String("out")
+		switch out {
+		case "broadcast":
+			bc, err = broadcasterFromContext(c)
+			if err != nil {
+				return err
+			}
+		case "hex", "json":
+		default:
+			return fmt.Errorf("unknown output mode: %q", out)
+		}
+
+		txsigner := types.LatestSignerForChainID(chainID)
+
+		var signedHex []string
+		for i, req := range reqs {
+			if req.Nonce != nil {
+				nonce = *req.Nonce
+			}
+
+			tx, err := req.toTransaction(nonce, chainID)
+			if err != nil {
+				return fmt.Errorf("error building transaction %d: %w", i, err)
+			}
+
+			signed, err := signer.SignTx(tx, txsigner)
+			if err != nil {
+				return fmt.Errorf("error signing transaction %d: %w", i, err)
+			}
+
+			rawtx, err := signed.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("error encoding transaction %d: %w", i, err)
+			}
+			rawhex := fmt.Sprintf("%x", rawtx)
+
+			if out == "broadcast" {
+				txhash, err := bc.Broadcast(rawhex)
+				if err != nil {
+					return fmt.Errorf("error broadcasting transaction %d (aborting pipeline): %w", i, err)
+				}
+				fmt.Println(txhash)
+			} else {
+				signedHex = append(signedHex, rawhex)
+			}
+
+			nonce++
+		}
+
+		switch out {
+		case "hex":
+			for _, h := range signedHex {
+				fmt.Println(h)
+			}
+		case "json":
+			data, err := json.Marshal(signedHex)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		case "broadcast":
+			// already printed as each tx was submitted
+		}
+
+		return nil
+	},
+}
+
+// batchTxRequest is one entry in a batch file.
+type batchTxRequest struct {
+	To       string  `json:"to"`
+	Value    string  `json:"value"`
+	Data     string  `json:"data"`
+	GasLimit string  `json:"gasLimit"`
+	GasPrice string  `json:"gasPrice"`
+	MaxFee   string  `json:"maxFee"`
+	MaxTip   string  `json:"maxPriorityFeePerGas"`
+	Nonce    *uint64 `json:"nonce"`
+}
+
+func (r *batchTxRequest) toTransaction(nonce uint64, chainID *big.Int) (*types.Transaction, error) {
+	var toaddr *common.Address
+	if r.To != "" {
+		a := common.HexToAddress(r.To)
+		toaddr = &a
+	}
+
+	val := big.NewInt(0)
+	if r.Value != "" {
+		v, err := Parse(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		val = v
+	}
+
+	datab := common.FromHex(r.Data)
+
+	gaslim := uint64(100000)
+	if r.GasLimit != "" {
+		n, ok := big.NewInt(0).SetString(r.GasLimit, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid gasLimit: %q", r.GasLimit)
+		}
+		gaslim = n.Uint64()
+	}
+
+	if r.MaxFee != "" || r.MaxTip != "" {
+		maxFee, ok := big.NewInt(0).SetString(r.MaxFee, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid maxFee: %q", r.MaxFee)
+		}
+		maxTip, ok := big.NewInt(0).SetString(r.MaxTip, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid maxPriorityFeePerGas: %q", r.MaxTip)
+		}
+
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        toaddr,
+			Value:     val,
+			Gas:       gaslim,
+			GasFeeCap: maxFee,
+			GasTipCap: maxTip,
+			Data:      datab,
+		}), nil
+	}
+
+	gasprice := big.NewInt(4000000000)
+	if r.GasPrice != "" {
+		n, ok := big.NewInt(0).SetString(r.GasPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid gasPrice: %q", r.GasPrice)
+		}
+		gasprice = n
+	}
+
+	if toaddr != nil {
+		return types.NewTransaction(nonce, *toaddr, val, gaslim, gasprice, datab), nil
+	}
+	return types.NewContractCreation(nonce, val, gaslim, gasprice, datab), nil
+}
+
+// loadBatchFile parses either a JSON array of batchTxRequest, or a file
+// with one JSON object per line.
+func loadBatchFile(path string) ([]batchTxRequest, error) {
+	if path == "" {
+		return nil, fmt.Errorf("must specify --file")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading batch file: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var reqs []batchTxRequest
+		if err := json.Unmarshal(data, &reqs); err != nil {
+			return nil, fmt.Errorf("error parsing batch file: %w", err)
+		}
+		return reqs, nil
+	}
+
+	var reqs []batchTxRequest
+	scan := bufio.NewScanner(strings.NewReader(trimmed))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if line == "" {
+			continue
+		}
+		var req batchTxRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			return nil, fmt.Errorf("error parsing batch file line %q: %w", line, err)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+
+	return reqs, nil
+}
+
+// startingNonce picks the nonce to sign the first transaction with: either
+// --startNonce, or the sender's current transaction count queried from
+// --rpc.
+func startingNonce(c *cli.Context, addr common.Address) (uint64, error) {
+	if n := c.Int64("startNonce"); n >= 0 {
+		return uint64(n), nil
+	}
+
+	rpc := c.String("rpc")
+	if rpc == "" {
+		return 0, fmt.Errorf("must specify --startNonce or --rpc to determine the starting nonce")
+	}
+
+	result, err := doJSONRPC(rpc, "eth_getTransactionCount", []interface{}{addr.Hex(), "pending"})
+	if err != nil {
+		return 0, fmt.Errorf("error querying starting nonce: %w", err)
+	}
+
+	n, ok := big.NewInt(0).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return 0, fmt.Errorf("error parsing nonce response: %q", result)
+	}
+
+	return n.Uint64(), nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli"
+)
+
+// broadcastFlags are the flags shared by commands that send a signed
+// transaction somewhere: push and batch.
+var broadcastFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "rpc",
+		Usage: "JSON-RPC endpoint (http/https URL) to submit the transaction to; requests are sent as HTTP POST, so ws/wss/ipc endpoints are not supported",
+	},
+	cli.StringFlag{
+		Name:  "backend",
+		Value: "rpc",
+		Usage: "broadcast backend to use: rpc, etherscan, or flashbots",
+	},
+	cli.StringFlag{
+		Name:  "network",
+		Value: "mainnet",
+		Usage: "network to submit to when using the etherscan backend: mainnet, goerli, sepolia, holesky, polygon",
+	},
+	cli.StringFlag{
+		Name:  "apiKey",
+		Usage: "API key for the etherscan backend",
+	},
+	cli.Int64Flag{
+		Name:  "blockNumber",
+		Usage: "target block number for the flashbots bundle",
+	},
+	cli.StringFlag{
+		Name:  "flashbotsKey",
+		Usage: "hex private key used to sign the X-Flashbots-Signature header (the searcher reputation key, separate from the key that signs the transaction)",
+	},
+}
+
+// Broadcaster sends a signed, RLP-encoded transaction to the network and
+// returns its hash.
+type Broadcaster interface {
+	Broadcast(rawtx string) (txhash string, err error)
+}
+
+// broadcasterFromContext builds the Broadcaster selected by --backend.
+func broadcasterFromContext(c *cli.Context) (Broadcaster, error) {
+	switch c.String("backend") {
+	case "rpc":
+		rpc := c.String("rpc")
+		if rpc == "" {
+			return nil, fmt.Errorf("must specify --rpc when using the rpc backend")
+		}
+		return &rpcBroadcaster{endpoint: rpc}, nil
+	case "etherscan":
+		return &etherscanBroadcaster{
+			network: c.String("network"),
+			apiKey:  c.String("apiKey"),
+		}, nil
+	case "flashbots":
+		keyhex := c.String("flashbotsKey")
+		if keyhex == "" {
+			return nil, fmt.Errorf("must specify --flashbotsKey when using the flashbots backend")
+		}
+		if c.Int64("blockNumber") <= 0 {
+			return nil, fmt.Errorf("must specify --blockNumber when using the flashbots backend")
+		}
+
+		keybytes, err := hex.DecodeString(strings.TrimPrefix(keyhex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding flashbots signing key: %w", err)
+		}
+		ecpriv, _ := btcec.PrivKeyFromBytes(btcec.S256(), keybytes)
+
+		return &flashbotsBroadcaster{
+			rpc:         c.String("rpc"),
+			blockNumber: uint64(c.Int64("blockNumber")),
+			signingKey:  ecpriv.ToECDSA(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown broadcast backend: %q", c.String("backend"))
+	}
+}
+
+type jsonrpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonrpcResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func doJSONRPC(endpoint, method string, params []interface{}) (string, error) {
+	req := jsonrpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  method,
+		Params:  params,
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var rpcresp jsonrpcResponse
+	if err := json.Unmarshal(data, &rpcresp); err != nil {
+		return "", fmt.Errorf("error parsing json-rpc response: %w (body: %s)", err, data)
+	}
+
+	if rpcresp.Error != nil {
+		return "", fmt.Errorf("json-rpc error %d: %s", rpcresp.Error.Code, rpcresp.Error.Message)
+	}
+
+	return rpcresp.Result, nil
+}
+
+// rpcBroadcaster submits a transaction via eth_sendRawTransaction over a
+// JSON-RPC endpoint, matching what go-ethereum's ethclient does under the
+// hood.
+type rpcBroadcaster struct {
+	endpoint string
+}
+
+func (b *rpcBroadcaster) Broadcast(rawtx string) (string, error) {
+	return doJSONRPC(b.endpoint, "eth_sendRawTransaction", []interface{}{"0x" + rawtx})
+}
+
+var etherscanHosts = map[string]string{
+	"mainnet": "https://api.etherscan.io/api",
+	"goerli":  "https://api-goerli.etherscan.io/api",
+	"sepolia": "https://api-sepolia.etherscan.io/api",
+	"holesky": "https://api-holesky.etherscan.io/api",
+	"polygon": "https://api.polygonscan.com/api",
+}
+
+// etherscanBroadcaster submits a transaction via the Etherscan-style
+// eth_sendRawTransaction proxy endpoint.
+type etherscanBroadcaster struct {
+	network string
+	apiKey  string
+}
+
+func (b *etherscanBroadcaster) Broadcast(rawtx string) (string, error) {
+	host, ok := etherscanHosts[b.network]
+	if !ok {
+		return "", fmt.Errorf("unknown etherscan network: %q", b.network)
+	}
+
+	url := fmt.Sprintf("%s?module=proxy&action=eth_sendRawTransaction&hex=0x%s", host, rawtx)
+	if b.apiKey != "" {
+		url += "&apikey=" + b.apiKey
+	}
+
+	resp, err := http.Post(url, "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var rpcresp jsonrpcResponse
+	if err := json.Unmarshal(data, &rpcresp); err != nil {
+		return "", fmt.Errorf("error parsing etherscan response: %w (body: %s)", err, data)
+	}
+
+	if rpcresp.Error != nil {
+		return "", fmt.Errorf("etherscan error %d: %s", rpcresp.Error.Code, rpcresp.Error.Message)
+	}
+
+	return rpcresp.Result, nil
+}
+
+// flashbotsBroadcaster submits a single-transaction bundle to a
+// Flashbots/MEV-Share relay via eth_sendBundle, targeting a specific block
+// and signing the request with the searcher's reputation key, as relays
+// require.
+type flashbotsBroadcaster struct {
+	rpc         string
+	blockNumber uint64
+	signingKey  *ecdsa.PrivateKey
+}
+
+func (b *flashbotsBroadcaster) Broadcast(rawtx string) (string, error) {
+	if b.rpc == "" {
+		return "", fmt.Errorf("must specify --rpc with the relay URL when using the flashbots backend")
+	}
+
+	bundle := map[string]interface{}{
+		"txs":         []string{"0x" + rawtx},
+		"blockNumber": fmt.Sprintf("0x%x", b.blockNumber),
+	}
+
+	req := jsonrpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_sendBundle",
+		Params:  []interface{}{bundle},
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpreq, err := http.NewRequest("POST", b.rpc, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	sigheader, err := flashbotsSignature(body, b.signingKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing flashbots request: %w", err)
+	}
+	httpreq.Header.Set("Content-Type", "application/json")
+	httpreq.Header.Set("X-Flashbots-Signature", sigheader)
+
+	resp, err := http.DefaultClient.Do(httpreq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var rpcresp jsonrpcResponse
+	if err := json.Unmarshal(data, &rpcresp); err != nil {
+		return "", fmt.Errorf("error parsing flashbots response: %w (body: %s)", err, data)
+	}
+
+	if rpcresp.Error != nil {
+		return "", fmt.Errorf("flashbots error %d: %s", rpcresp.Error.Code, rpcresp.Error.Message)
+	}
+
+	return rpcresp.Result, nil
+}
+
+// flashbotsSignature produces the "<address>:<signature>" header relays
+// require: a personal_sign over the hex-string encoding of keccak256(body)
+// by the searcher's reputation key.
+func flashbotsSignature(body []byte, key *ecdsa.PrivateKey) (string, error) {
+	hash := fmt.Sprintf("0x%x", crypto.Keccak256(body))
+
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), key)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	return fmt.Sprintf("%s:0x%x", addr.Hex(), sig), nil
+}
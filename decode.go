@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// describeTx renders tx in a human-readable form: sender, recipient, value
+// and gas price/fee fields in ether/gwei, and - if the call data decodes -
+// the method name and arguments.
+func describeTx(tx *types.Transaction, chainID *big.Int, abiPath string) (string, error) {
+	var buf strings.Builder
+
+	signer := types.LatestSignerForChainID(chainID)
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		fmt.Fprintf(&buf, "from:     <could not recover sender: %s>\n", err)
+	} else {
+		fmt.Fprintf(&buf, "from:     %s\n", from.Hex())
+	}
+
+	if tx.To() != nil {
+		fmt.Fprintf(&buf, "to:       %s\n", tx.To().Hex())
+	} else {
+		fmt.Fprintf(&buf, "to:       <contract creation>\n")
+	}
+
+	fmt.Fprintf(&buf, "nonce:    %d\n", tx.Nonce())
+	fmt.Fprintf(&buf, "value:    %s eth\n", formatEther(tx.Value()))
+	fmt.Fprintf(&buf, "gasLimit: %d\n", tx.Gas())
+
+	switch tx.Type() {
+	case types.LegacyTxType:
+		fmt.Fprintf(&buf, "gasPrice: %s gwei\n", formatGwei(tx.GasPrice()))
+	case types.AccessListTxType:
+		fmt.Fprintf(&buf, "gasPrice: %s gwei\n", formatGwei(tx.GasPrice()))
+		writeAccessList(&buf, tx.AccessList())
+	case types.DynamicFeeTxType:
+		fmt.Fprintf(&buf, "maxFeePerGas:         %s gwei\n", formatGwei(tx.GasFeeCap()))
+		fmt.Fprintf(&buf, "maxPriorityFeePerGas: %s gwei\n", formatGwei(tx.GasTipCap()))
+		writeAccessList(&buf, tx.AccessList())
+	}
+
+	decoded, err := decodeData(tx.Data(), abiPath)
+	if err != nil {
+		fmt.Fprintf(&buf, "data:     <could not decode: %s>\n", err)
+	} else if decoded != "" {
+		fmt.Fprintf(&buf, "call:     %s\n", decoded)
+	} else if len(tx.Data()) > 0 {
+		fmt.Fprintf(&buf, "data:     0x%x\n", tx.Data())
+	}
+
+	return buf.String(), nil
+}
+
+func writeAccessList(buf *strings.Builder, al types.AccessList) {
+	if len(al) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "accessList:\n")
+	for _, tup := range al {
+		fmt.Fprintf(buf, "  %s\n", tup.Address.Hex())
+		for _, key := range tup.StorageKeys {
+			fmt.Fprintf(buf, "    %s\n", key.Hex())
+		}
+	}
+}
+
+// decodeData turns call data into a readable call description. If abiPath
+// is set, it's decoded with the given contract ABI into a
+// "method(arg1, arg2, ...)" string. Otherwise the 4-byte selector is looked
+// up in a local cache, falling back to 4byte.directory, and the result is
+// the matching signature followed by the argument data as raw hex; the
+// arguments are not decoded on that path.
+func decodeData(data []byte, abiPath string) (string, error) {
+	if len(data) < 4 {
+		return "", nil
+	}
+
+	if abiPath != "" {
+		return decodeWithABI(data, abiPath)
+	}
+
+	return decodeWithFourByte(data)
+}
+
+func decodeWithABI(data []byte, abiPath string) (string, error) {
+	f, err := os.Open(abiPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening abi file: %w", err)
+	}
+	defer f.Close()
+
+	parsed, err := abi.JSON(f)
+	if err != nil {
+		return "", fmt.Errorf("error parsing abi file: %w", err)
+	}
+
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return "", err
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", fmt.Errorf("error unpacking arguments for %s: %w", method.Name, err)
+	}
+
+	return formatCall(method.Name, method.Inputs, args), nil
+}
+
+// fourByteCacheDir is where previously-resolved selectors are cached so
+// repeated lookups don't need to hit the network.
+var fourByteCacheDir = filepath.Join(os.Getenv("HOME"), ".ethtx", "4byte")
+
+func decodeWithFourByte(data []byte) (string, error) {
+	selector := fmt.Sprintf("%x", data[:4])
+
+	sig, err := lookupFourByteCache(selector)
+	if err != nil {
+		sig, err = lookupFourByteDirectory(selector)
+		if err != nil {
+			return "", err
+		}
+		if sig != "" {
+			_ = storeFourByteCache(selector, sig)
+		}
+	}
+
+	if sig == "" {
+		return fmt.Sprintf("<unknown method 0x%s>", selector), nil
+	}
+
+	return fmt.Sprintf("%s 0x%x", sig, data[4:]), nil
+}
+
+func lookupFourByteCache(selector string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(fourByteCacheDir, selector))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func storeFourByteCache(selector, sig string) error {
+	if err := os.MkdirAll(fourByteCacheDir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(fourByteCacheDir, selector), []byte(sig), 0644)
+}
+
+type fourByteResponse struct {
+	Results []struct {
+		TextSignature string `json:"text_signature"`
+	} `json:"results"`
+}
+
+// lookupFourByteDirectory queries the 4byte.directory API for a selector,
+// returning the oldest (lowest id, first registered) matching signature.
+func lookupFourByteDirectory(selector string) (string, error) {
+	url := "https://www.4byte.directory/api/v1/signatures/?hex_signature=0x" + selector
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed fourByteResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", fmt.Errorf("error parsing 4byte.directory response: %w", err)
+	}
+
+	if len(parsed.Results) == 0 {
+		return "", nil
+	}
+
+	return parsed.Results[len(parsed.Results)-1].TextSignature, nil
+}
+
+func formatCall(name string, inputs abi.Arguments, args []interface{}) string {
+	parts := make([]string, len(inputs))
+	for i, in := range inputs {
+		argname := in.Name
+		if argname == "" {
+			argname = fmt.Sprintf("arg%d", i)
+		}
+		parts[i] = fmt.Sprintf("%s=%v", argname, args[i])
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", "))
+}
+
+// formatEther and formatGwei are the inverse of Parse: they turn wei
+// amounts back into a human-scaled decimal string.
+func formatEther(wei *big.Int) string {
+	return formatScaled(wei, 18)
+}
+
+func formatGwei(wei *big.Int) string {
+	return formatScaled(wei, 9)
+}
+
+func formatScaled(wei *big.Int, decimals int64) string {
+	if wei == nil {
+		return "0"
+	}
+
+	denom := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(decimals), nil)
+	whole := big.NewInt(0).Div(wei, denom)
+	rem := big.NewInt(0).Mod(wei, denom)
+
+	if rem.Sign() == 0 {
+		return whole.String()
+	}
+
+	fracstr := rem.String()
+	for len(fracstr) < int(decimals) {
+		fracstr = "0" + fracstr
+	}
+	fracstr = strings.TrimRight(fracstr, "0")
+
+	return fmt.Sprintf("%s.%s", whole.String(), fracstr)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli"
+)
+
+var signTyped = cli.Command{
+	Name:  "signTyped",
+	Usage: "sign an EIP-712 typed data payload",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "file",
+			Usage: "path to the EIP-712 JSON payload, defaults to stdin",
+		},
+	}, keySourceFlags...),
+	Action: func(c *cli.Context) error {
+		var raw []byte
+		var err error
+		if path := c.String("file"); path != "" {
+			raw, err = ioutil.ReadFile(path)
+		} else {
+			raw, err = ioutil.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading typed data: %w", err)
+		}
+
+		var td typedData
+		if err := json.Unmarshal(raw, &td); err != nil {
+			return fmt.Errorf("error parsing typed data: %w", err)
+		}
+
+		domainSep, err := td.hashStruct("EIP712Domain", td.Domain)
+		if err != nil {
+			return fmt.Errorf("error hashing domain: %w", err)
+		}
+
+		msgHash, err := td.hashStruct(td.PrimaryType, td.Message)
+		if err != nil {
+			return fmt.Errorf("error hashing message: %w", err)
+		}
+
+		signer, err := signerFromContext(c)
+		if err != nil {
+			return err
+		}
+
+		sig, err := signer.SignTypedData(domainSep, msgHash)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("0x%x\n", sig)
+		return nil
+	},
+}
+
+// typedData is the EIP-712 {types, primaryType, domain, message} payload.
+type typedData struct {
+	Types       map[string][]typedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+type typedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// hashStruct computes keccak256(typeHash || enc(field1) || enc(field2) || ...).
+func (td *typedData) hashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.Types[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", primaryType)
+	}
+
+	buf := append([]byte{}, td.typeHash(primaryType)...)
+
+	for _, f := range fields {
+		enc, err := td.encodeField(f.Type, data[f.Name])
+		if err != nil {
+			return nil, fmt.Errorf("error encoding field %q: %w", f.Name, err)
+		}
+		buf = append(buf, enc...)
+	}
+
+	return crypto.Keccak256(buf), nil
+}
+
+// typeHash is keccak256(encodeType(primaryType)).
+func (td *typedData) typeHash(primaryType string) []byte {
+	return crypto.Keccak256([]byte(td.encodeType(primaryType)))
+}
+
+// encodeType lists primaryType's own fields followed by every struct type
+// it references (directly or transitively), sorted alphabetically, as
+// required by EIP-712.
+func (td *typedData) encodeType(primaryType string) string {
+	deps := map[string]bool{}
+	td.collectDeps(primaryType, deps)
+	delete(deps, primaryType)
+
+	sorted := make([]string, 0, len(deps))
+	for t := range deps {
+		sorted = append(sorted, t)
+	}
+	sort.Strings(sorted)
+
+	var buf strings.Builder
+	buf.WriteString(td.encodeTypeFields(primaryType))
+	for _, t := range sorted {
+		buf.WriteString(td.encodeTypeFields(t))
+	}
+
+	return buf.String()
+}
+
+func (td *typedData) encodeTypeFields(t string) string {
+	fields := td.Types[t]
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s %s", f.Type, f.Name)
+	}
+	return fmt.Sprintf("%s(%s)", t, strings.Join(parts, ","))
+}
+
+func (td *typedData) collectDeps(t string, deps map[string]bool) {
+	baseType := t
+	if i := strings.Index(t, "["); i >= 0 {
+		baseType = t[:i]
+	}
+	if _, ok := td.Types[baseType]; !ok || deps[baseType] {
+		return
+	}
+	deps[baseType] = true
+
+	for _, f := range td.Types[baseType] {
+		td.collectDeps(f.Type, deps)
+	}
+}
+
+// encodeField ABI-encodes a single EIP-712 field value: atomic types are
+// padded to 32 bytes (left-padded, except fixed-size bytesN values, which
+// are right-padded), dynamic bytes/strings are keccak256-hashed
+// first, arrays are the keccak256 of their concatenated encoded elements,
+// and struct fields recurse via hashStruct.
+func (td *typedData) encodeField(typ string, value interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "]") {
+		return td.encodeArrayField(typ, value)
+	}
+
+	if _, isStruct := td.Types[typ]; isStruct {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object for struct type %q", typ)
+		}
+		return td.hashStruct(typ, m)
+	}
+
+	switch {
+	case typ == "string":
+		s, _ := value.(string)
+		return crypto.Keccak256([]byte(s)), nil
+	case typ == "bytes":
+		b, err := hexOrBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case typ == "bool":
+		b, _ := value.(bool)
+		if b {
+			return math.PaddedBigBytes(big.NewInt(1), 32), nil
+		}
+		return math.PaddedBigBytes(big.NewInt(0), 32), nil
+	case typ == "address":
+		s, _ := value.(string)
+		addr := common.HexToAddress(s)
+		return common.LeftPadBytes(addr.Bytes(), 32), nil
+	case strings.HasPrefix(typ, "bytes"):
+		b, err := hexOrBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		padded := make([]byte, 32)
+		copy(padded, b)
+		return padded, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() < 0 {
+			// Negative signed ints (int8..int256) are encoded as their
+			// two's-complement 256-bit word, same as abi.U256.
+			n = math.U256(n)
+		}
+		return math.PaddedBigBytes(n, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+}
+
+func (td *typedData) encodeArrayField(typ string, value interface{}) ([]byte, error) {
+	elemType := typ[:strings.LastIndex(typ, "[")]
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected array for type %q", typ)
+	}
+
+	var buf []byte
+	for _, item := range items {
+		enc, err := td.encodeField(elemType, item)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, enc...)
+	}
+
+	return crypto.Keccak256(buf), nil
+}
+
+func hexOrBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected hex string")
+	}
+	b := common.FromHex(s)
+	if b == nil {
+		return nil, fmt.Errorf("invalid hex string: %q", s)
+	}
+	return b, nil
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case string:
+		n, ok := big.NewInt(0).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer: %q", v)
+		}
+		return n, nil
+	case float64:
+		return big.NewInt(int64(v)), nil
+	default:
+		return nil, fmt.Errorf("expected number or numeric string")
+	}
+}
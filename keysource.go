@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli"
+)
+
+// keySourceFlags are the flags shared by every command that needs to sign
+// something: a raw hex private key, a V3 keystore file, or a Ledger
+// hardware wallet. Keeping the raw key off the command line (and out of
+// shell history) is the whole point of the keystore/ledger paths.
+var keySourceFlags = []cli.Flag{
+	cli.StringFlag{
+		Name: "privkey",
+	},
+	cli.StringFlag{
+		Name:  "keystore",
+		Usage: "path to a V3 Web3 Secret Storage JSON keyfile",
+	},
+	cli.StringFlag{
+		Name:  "password",
+		Usage: "password for --keystore",
+	},
+	cli.StringFlag{
+		Name:  "password-file",
+		Usage: "path to a file containing the password for --keystore",
+	},
+	cli.BoolFlag{
+		Name:  "ledger",
+		Usage: "sign using the first Ledger hardware wallet found",
+	},
+}
+
+// TxSigner abstracts over where the signing key actually lives, so callers
+// don't need to care whether it's a raw hex key, a keystore file, or a
+// Ledger plugged into USB.
+type TxSigner interface {
+	Address() common.Address
+	SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error)
+
+	// SignTypedData signs an EIP-712 digest given its two constituent
+	// hashes, domainSeparator and messageHash (each 32 bytes), returning a
+	// 65-byte r||s||v signature. Hardware signers need the two hashes kept
+	// apart because the device computes the final digest itself.
+	SignTypedData(domainSeparator, messageHash []byte) ([]byte, error)
+}
+
+// signerFromContext picks a TxSigner based on the keySourceFlags set on c.
+// Exactly one of --privkey, --keystore, or --ledger must be given.
+func signerFromContext(c *cli.Context) (TxSigner, error) {
+	privkey := c.String("privkey")
+	ks := c.String("keystore")
+	ledger := c.Bool("ledger")
+
+	var set int
+	for _, b := range []bool{privkey != "", ks != "", ledger} {
+		if b {
+			set++
+		}
+	}
+	if set == 0 {
+		return nil, fmt.Errorf("must specify one of --privkey, --keystore, or --ledger")
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("must specify only one of --privkey, --keystore, or --ledger")
+	}
+
+	switch {
+	case privkey != "":
+		return privkeySigner(privkey)
+	case ks != "":
+		return keystoreSigner(ks, c.String("password"), c.String("password-file"))
+	default:
+		return ledgerSigner()
+	}
+}
+
+type ecdsaSigner struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+func privkeySigner(hexkey string) (TxSigner, error) {
+	privk, err := hex.DecodeString(strings.TrimPrefix(hexkey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding private key: %w", err)
+	}
+
+	ecpriv, _ := btcec.PrivKeyFromBytes(btcec.S256(), privk)
+	key := ecpriv.ToECDSA()
+
+	return &ecdsaSigner{
+		key:  key,
+		addr: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+func (s *ecdsaSigner) Address() common.Address {
+	return s.addr
+}
+
+func (s *ecdsaSigner) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	return types.SignTx(tx, signer, s.key)
+}
+
+func (s *ecdsaSigner) SignTypedData(domainSeparator, messageHash []byte) ([]byte, error) {
+	digest := crypto.Keccak256(append(append([]byte{0x19, 0x01}, domainSeparator...), messageHash...))
+
+	sig, err := crypto.Sign(digest, s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+
+	return sig, nil
+}
+
+// keystoreSigner decrypts a V3 Web3 Secret Storage JSON keyfile (scrypt or
+// pbkdf2 KDF) and returns a signer backed by the recovered key.
+func keystoreSigner(path, password, passwordFile string) (TxSigner, error) {
+	if password == "" && passwordFile != "" {
+		data, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading password file: %w", err)
+		}
+		password = strings.TrimSpace(string(data))
+	}
+
+	keyjson, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore file: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting keystore file: %w", err)
+	}
+
+	return &ecdsaSigner{
+		key:  key.PrivateKey,
+		addr: key.Address,
+	}, nil
+}
+
+// ledgerWallet holds the usbwallet plumbing needed to sign with a connected
+// Ledger device. ledgerSigner opens the connection when it creates one.
+type ledgerWallet struct {
+	hub     *usbwallet.Hub
+	wallet  accounts.Wallet
+	account accounts.Account
+}
+
+func ledgerSigner() (TxSigner, error) {
+	hub, err := usbwallet.NewLedgerHub()
+	if err != nil {
+		return nil, fmt.Errorf("error opening ledger hub: %w", err)
+	}
+
+	wallets := hub.Wallets()
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("no ledger device found")
+	}
+	wallet := wallets[0]
+
+	if err := wallet.Open(""); err != nil {
+		return nil, fmt.Errorf("error opening ledger wallet: %w", err)
+	}
+
+	account, err := wallet.Derive(accounts.DefaultBaseDerivationPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving ledger account: %w", err)
+	}
+
+	return &ledgerWallet{hub: hub, wallet: wallet, account: account}, nil
+}
+
+func (l *ledgerWallet) Address() common.Address {
+	return l.account.Address
+}
+
+func (l *ledgerWallet) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	return l.wallet.SignTx(l.account, tx, signer.ChainID())
+}
+
+// typedMessageSigner is implemented by the usbwallet Ledger driver: the
+// Ledger Ethereum app has a dedicated "sign EIP-712 message" instruction
+// that takes the domain and message hashes separately and computes the
+// final digest on-device.
+type typedMessageSigner interface {
+	SignTypedMessage(account accounts.Account, domainHash, messageHash []byte) ([]byte, error)
+}
+
+func (l *ledgerWallet) SignTypedData(domainSeparator, messageHash []byte) ([]byte, error) {
+	signer, ok := l.wallet.(typedMessageSigner)
+	if !ok {
+		return nil, fmt.Errorf("connected ledger device does not support EIP-712 typed data signing")
+	}
+
+	sig, err := signer.SignTypedMessage(l.account, domainSeparator, messageHash)
+	if err != nil {
+		return nil, fmt.Errorf("error signing typed data on ledger: %w", err)
+	}
+
+	return sig, nil
+}
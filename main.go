@@ -3,17 +3,15 @@ package main
 import (
 	"bufio"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
-	"net/http"
 	"os"
 	"strings"
 
-	"github.com/btcsuite/btcd/btcec"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/urfave/cli"
 )
 
@@ -24,6 +22,8 @@ func main() {
 		mktx,
 		showTx,
 		pushTx,
+		signTyped,
+		batchTx,
 	}
 
 	app.RunAndExitOnError()
@@ -31,10 +31,7 @@ func main() {
 
 var mktx = cli.Command{
 	Name: "new",
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name: "privkey",
-		},
+	Flags: append([]cli.Flag{
 		cli.StringFlag{
 			Name: "to",
 		},
@@ -55,7 +52,29 @@ var mktx = cli.Command{
 		cli.Int64Flag{
 			Name: "nonce",
 		},
-	},
+		cli.StringFlag{
+			Name:  "chainID",
+			Value: "1",
+			Usage: "chain id to sign the transaction for",
+		},
+		cli.StringFlag{
+			Name:  "txType",
+			Value: "legacy",
+			Usage: "type of transaction to build: legacy, accessList, or dynamicFee",
+		},
+		cli.StringFlag{
+			Name:  "maxFeePerGas",
+			Usage: "max fee per gas, for dynamicFee transactions",
+		},
+		cli.StringFlag{
+			Name:  "maxPriorityFeePerGas",
+			Usage: "max priority fee per gas, for dynamicFee transactions",
+		},
+		cli.StringFlag{
+			Name:  "accessList",
+			Usage: "path to a JSON file containing an access list: [{address, storageKeys}]",
+		},
+	}, keySourceFlags...),
 	Action: func(c *cli.Context) error {
 		nonce := c.Int64("nonce")
 		gasprice := c.String("gasPrice")
@@ -63,7 +82,6 @@ var mktx = cli.Command{
 		val := c.String("value")
 		data := c.String("data")
 		to := c.String("to")
-		privkey := c.String("privkey")
 
 		var toset bool
 		var toaddr common.Address
@@ -76,11 +94,6 @@ var mktx = cli.Command{
 			return err
 		}
 
-		gaspr, ok := big.NewInt(0).SetString(gasprice, 10)
-		if !ok {
-			return fmt.Errorf("invalid value for gas price")
-		}
-
 		gaslim, ok := big.NewInt(0).SetString(gaslimit, 10)
 		if !ok {
 			return fmt.Errorf("invalid value for gas limit")
@@ -91,22 +104,78 @@ var mktx = cli.Command{
 			return fmt.Errorf("bad hex data: %q", data)
 		}
 
-		var tx *types.Transaction
-		if toset {
-			tx = types.NewTransaction(uint64(nonce), toaddr, ethval, gaslim, gaspr, datab)
-		} else {
-			tx = types.NewContractCreation(uint64(nonce), ethval, gaslim, gaspr, datab)
+		chainID, ok := big.NewInt(0).SetString(c.String("chainID"), 10)
+		if !ok {
+			return fmt.Errorf("invalid value for chain id")
 		}
 
-		privk, err := hex.DecodeString(privkey)
+		accessList, err := loadAccessList(c.String("accessList"))
 		if err != nil {
-			return fmt.Errorf("error decoding private key")
+			return err
+		}
+
+		var tx *types.Transaction
+		switch c.String("txType") {
+		case "legacy":
+			gaspr, ok := big.NewInt(0).SetString(gasprice, 10)
+			if !ok {
+				return fmt.Errorf("invalid value for gas price")
+			}
+
+			if toset {
+				tx = types.NewTransaction(uint64(nonce), toaddr, ethval, gaslim.Uint64(), gaspr, datab)
+			} else {
+				tx = types.NewContractCreation(uint64(nonce), ethval, gaslim.Uint64(), gaspr, datab)
+			}
+		case "accessList":
+			gaspr, ok := big.NewInt(0).SetString(gasprice, 10)
+			if !ok {
+				return fmt.Errorf("invalid value for gas price")
+			}
+
+			tx = types.NewTx(&types.AccessListTx{
+				ChainID:    chainID,
+				Nonce:      uint64(nonce),
+				GasPrice:   gaspr,
+				Gas:        gaslim.Uint64(),
+				To:         addrPtr(toset, toaddr),
+				Value:      ethval,
+				Data:       datab,
+				AccessList: accessList,
+			})
+		case "dynamicFee":
+			maxFee, ok := big.NewInt(0).SetString(c.String("maxFeePerGas"), 10)
+			if !ok {
+				return fmt.Errorf("invalid value for max fee per gas")
+			}
+
+			maxPriorityFee, ok := big.NewInt(0).SetString(c.String("maxPriorityFeePerGas"), 10)
+			if !ok {
+				return fmt.Errorf("invalid value for max priority fee per gas")
+			}
+
+			tx = types.NewTx(&types.DynamicFeeTx{
+				ChainID:    chainID,
+				Nonce:      uint64(nonce),
+				GasTipCap:  maxPriorityFee,
+				GasFeeCap:  maxFee,
+				Gas:        gaslim.Uint64(),
+				To:         addrPtr(toset, toaddr),
+				Value:      ethval,
+				Data:       datab,
+				AccessList: accessList,
+			})
+		default:
+			return fmt.Errorf("unknown tx type: %q", c.String("txType"))
 		}
 
-		ecpriv, _ := btcec.PrivKeyFromBytes(btcec.S256(), privk)
+		txsigner, err := signerFromContext(c)
+		if err != nil {
+			return err
+		}
 
-		signer := types.NewEIP155Signer(big.NewInt(1))
-		signed, err := types.SignTx(tx, signer, ecpriv.ToECDSA())
+		signer := types.LatestSignerForChainID(chainID)
+		signed, err := txsigner.SignTx(tx, signer)
 		if err != nil {
 			return err
 		}
@@ -117,8 +186,66 @@ var mktx = cli.Command{
 	},
 }
 
+// addrPtr returns a pointer to addr if set is true, or nil for a contract
+// creation.
+func addrPtr(set bool, addr common.Address) *common.Address {
+	if !set {
+		return nil
+	}
+	return &addr
+}
+
+type accessListEntry struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// loadAccessList reads a JSON file of the form [{address, storageKeys}] and
+// converts it into a go-ethereum AccessList. An empty path yields a nil
+// (empty) access list.
+func loadAccessList(path string) (types.AccessList, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading access list file: %w", err)
+	}
+
+	var entries []accessListEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing access list file: %w", err)
+	}
+
+	al := make(types.AccessList, len(entries))
+	for i, e := range entries {
+		keys := make([]common.Hash, len(e.StorageKeys))
+		for j, k := range e.StorageKeys {
+			keys[j] = common.HexToHash(k)
+		}
+		al[i] = types.AccessTuple{
+			Address:     common.HexToAddress(e.Address),
+			StorageKeys: keys,
+		}
+	}
+
+	return al, nil
+}
+
 var showTx = cli.Command{
 	Name: "show",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "chainID",
+			Value: "1",
+			Usage: "chain id to use when recovering the sender",
+		},
+		cli.StringFlag{
+			Name:  "abi",
+			Usage: "path to a contract ABI JSON file to decode call data with",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		if !c.Args().Present() {
 			return fmt.Errorf("must pass hex encoded transaction to parse")
@@ -135,17 +262,28 @@ var showTx = cli.Command{
 		}
 
 		var tx types.Transaction
-		if err := rlp.DecodeBytes(v, &tx); err != nil {
+		if err := tx.UnmarshalBinary(v); err != nil {
 			return err
 		}
 
-		fmt.Println(tx.String())
+		chainID, ok := big.NewInt(0).SetString(c.String("chainID"), 10)
+		if !ok {
+			return fmt.Errorf("invalid value for chain id")
+		}
+
+		desc, err := describeTx(&tx, chainID, c.String("abi"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(desc)
 		return nil
 	},
 }
 
 var pushTx = cli.Command{
-	Name: "push",
+	Name:  "push",
+	Flags: broadcastFlags,
 	Action: func(c *cli.Context) error {
 		if !c.Args().Present() {
 			return fmt.Errorf("must pass hex encoded transaction to parse")
@@ -162,7 +300,7 @@ var pushTx = cli.Command{
 		}
 
 		var tx types.Transaction
-		if err := rlp.DecodeBytes(v, &tx); err != nil {
+		if err := tx.UnmarshalBinary(v); err != nil {
 			return err
 		}
 
@@ -172,26 +310,19 @@ var pushTx = cli.Command{
 			return nil
 		}
 
-		return postTx(hexval)
-	},
-}
-
-func postTx(hex string) error {
-	url := "https://api.etherscan.io/api?module=proxy&action=eth_sendRawTransaction&hex=" + hex
-	resp, err := http.Post(url, "", nil)
-	if err != nil {
-		return err
-	}
-
-	defer resp.Body.Close()
+		broadcaster, err := broadcasterFromContext(c)
+		if err != nil {
+			return err
+		}
 
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
+		txhash, err := broadcaster.Broadcast(hexval)
+		if err != nil {
+			return err
+		}
 
-	fmt.Println(string(data))
-	return nil
+		fmt.Println("transaction hash:", txhash)
+		return nil
+	},
 }
 
 func Parse(val string) (*big.Int, error) {